@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"stream-server/cluster"
+	"stream-server/sources"
+	"stream-server/store"
+)
+
+// IsLeader reports whether this replica should drive MediaMTX directly.
+// A single-node deployment (no Elector configured) is always its own
+// leader.
+func (s *Server) IsLeader() bool {
+	if s.elector == nil {
+		return true
+	}
+	return s.elector.IsLeader()
+}
+
+// runClusterElection claims or renews this replica's leadership lease every
+// cluster.RenewInterval. It blocks and should be run in its own goroutine.
+func (s *Server) runClusterElection() {
+	ticker := time.NewTicker(cluster.RenewInterval)
+	defer ticker.Stop()
+	for {
+		wasLeader := s.IsLeader()
+		if err := s.elector.Claim(); err != nil {
+			logger.Warn("cluster: claim failed", "error", err)
+		} else if isLeader := s.IsLeader(); isLeader != wasLeader {
+			logger.Info("cluster: leadership changed", "leader", isLeader, "status", s.elector.Status())
+		}
+		<-ticker.C
+	}
+}
+
+// reconcile diffs the configured streams against MediaMTX's actual paths
+// and applies AddPath/DeletePath until they converge. It only acts while
+// this replica is the leader; followers skip it entirely rather than
+// racing the leader's writes.
+func (s *Server) reconcile() {
+	if !s.IsLeader() {
+		return
+	}
+
+	streams, err := s.store.List()
+	if err != nil {
+		logger.Warn("reconcile: cannot list streams", "error", err)
+		return
+	}
+	paths, err := s.mtx.ListPaths()
+	if err != nil {
+		logger.Warn("reconcile: cannot list MediaMTX paths", "error", err)
+		return
+	}
+
+	want := make(map[string]*store.Stream, len(streams))
+	for _, st := range streams {
+		want[st.Name] = st
+	}
+
+	for name, st := range want {
+		if _, ok := paths[name]; ok {
+			continue
+		}
+		resolved, err := sources.Detect(sources.Request{
+			Kind:          st.Kind,
+			SourceURL:     st.SourceURL,
+			SRTStreamID:   st.SRTStreamID,
+			RTSPTransport: st.RTSPTransport,
+		})
+		if err != nil {
+			logger.Warn("reconcile: cannot resolve source", "name", name, "error", err)
+			continue
+		}
+		mtxSource := resolved.Source
+		if resolved.Kind == sources.KindPublisher {
+			mtxSource = ""
+		}
+		extra := mergeFields(resolved.Extra, recordingFields(s.cfg, name, st.RecordEnabled, st.RecordFormat, time.Duration(st.RecordSegmentDuration), time.Duration(st.RecordRetention)))
+		if err := s.mtx.AddPath(name, mtxSource, extra); err != nil {
+			logger.Warn("reconcile: cannot add path", "name", name, "error", err)
+			continue
+		}
+		logger.Info("reconcile: added missing path", "name", name)
+	}
+
+	for name := range paths {
+		if _, ok := want[name]; ok {
+			continue
+		}
+		if err := s.mtx.DeletePath(name); err != nil {
+			logger.Warn("reconcile: cannot delete stray path", "name", name, "error", err)
+			continue
+		}
+		logger.Info("reconcile: deleted stray path", "name", name)
+	}
+}
+
+// runReconciler reconciles immediately, then on every tick of interval. It
+// blocks and should be run in its own goroutine.
+func (s *Server) runReconciler(interval time.Duration) {
+	s.reconcile()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.reconcile()
+	}
+}
+
+// applyRequest is the body of POST /internal/apply: one AddPath/DeletePath
+// a follower asks the leader to perform on its behalf.
+type applyRequest struct {
+	Action string                 `json:"action"` // "add" or "delete"
+	Name   string                 `json:"name"`
+	Source string                 `json:"source,omitempty"`
+	Extra  map[string]interface{} `json:"extra,omitempty"`
+}
+
+// applyPath adds name to MediaMTX, either directly (if this replica is the
+// leader) or by forwarding the request to whichever replica currently is.
+func (s *Server) applyPath(name, source string, extra map[string]interface{}) error {
+	if s.IsLeader() {
+		return s.mtx.AddPath(name, source, extra)
+	}
+	return s.forwardApply(applyRequest{Action: "add", Name: name, Source: source, Extra: extra})
+}
+
+// applyDelete removes name from MediaMTX, either directly (if this replica
+// is the leader) or by forwarding the request to whichever replica
+// currently is.
+func (s *Server) applyDelete(name string) error {
+	if s.IsLeader() {
+		return s.mtx.DeletePath(name)
+	}
+	return s.forwardApply(applyRequest{Action: "delete", Name: name})
+}
+
+// forwardApply proxies req to the current leader's /internal/apply
+// endpoint, authenticated by the shared CLUSTER_SECRET.
+func (s *Server) forwardApply(req applyRequest) error {
+	leader := s.elector.Status().Leader
+	if leader == "" {
+		return fmt.Errorf("cluster: no leader elected yet")
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequest("POST", strings.TrimRight(leader, "/")+"/internal/apply", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Cluster-Secret", s.cfg.ClusterSecret)
+
+	resp, err := s.mtx.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("cluster: cannot reach leader %s: %w", leader, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("cluster: leader rejected apply: %s", string(respBody))
+	}
+	return nil
+}
+
+// POST /internal/apply — applies a path add/delete to MediaMTX on behalf
+// of a follower replica. It's authenticated by the shared CLUSTER_SECRET
+// rather than the normal Authenticator, since it's a replica-to-replica
+// call rather than an end-user request.
+func (s *Server) handleInternalApply(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.ClusterSecret == "" || r.Header.Get("X-Cluster-Secret") != s.cfg.ClusterSecret {
+		jsonError(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	var req applyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonError(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	switch req.Action {
+	case "add":
+		err = s.mtx.AddPath(req.Name, req.Source, req.Extra)
+	case "delete":
+		err = s.mtx.DeletePath(req.Name)
+	default:
+		jsonError(w, "unknown action", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	jsonOK(w, map[string]string{"applied": req.Action})
+}
+
+// GET /api/cluster — reports this replica's view of the cluster, for
+// operator debugging during HA rollouts.
+func (s *Server) handleCluster(w http.ResponseWriter, r *http.Request) {
+	if s.elector == nil {
+		jsonOK(w, cluster.Status{Self: s.cfg.ClusterSelf, Leader: s.cfg.ClusterSelf})
+		return
+	}
+	jsonOK(w, s.elector.Status())
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// logger is the process-wide structured logger, configured from
+// LOG_FORMAT (text|json, default text) and LOG_LEVEL (debug|info|warn|error,
+// default info) at process start.
+var logger = newLogger()
+
+func newLogger() *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLevel(env("LOG_LEVEL", "info"))}
+
+	var handler slog.Handler
+	if strings.EqualFold(env("LOG_FORMAT", "text"), "json") {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+	return slog.New(handler)
+}
+
+func parseLevel(raw string) slog.Level {
+	switch strings.ToLower(raw) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
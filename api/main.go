@@ -3,16 +3,22 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
-	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"stream-server/auth"
+	"stream-server/cluster"
+	"stream-server/metrics"
+	"stream-server/sources"
+	"stream-server/store"
 )
 
 // ============================================================
@@ -22,22 +28,74 @@ import (
 type Config struct {
 	ListenAddr  string // API server listen address
 	MediaMTXAPI string // MediaMTX Control API (internal Docker URL)
+	PlaybackAPI string // MediaMTX playback server (serves recorded segments)
+	Host        string // MediaMTX host as reachable by encoders (for ingress URLs)
 	WebRTCPort  string // MediaMTX WebRTC port (for browser URLs)
 	HLSPort     string // MediaMTX HLS port
 	RTSPPort    string // MediaMTX RTSP port
-	StorePath   string // JSON file for stream persistence
+	RTMPPort    string // MediaMTX RTMP port
+	SRTPort     string // MediaMTX SRT port
+	StorePath   string // JSON file for stream persistence (driver "json")
 	WebDir      string // Static web UI directory
+
+	StoreDriver string // STORE_DRIVER: json (default), sqlite, postgres
+	StoreDSN    string // STORE_DSN: sqlite/postgres connection string; falls back to StorePath for json
+
+	RecordPath            string        // MediaMTX recordPath template, e.g. ./data/recordings/%path/%Y-%m-%d_%H-%M-%S-%f
+	RecordJanitorInterval time.Duration // how often to enforce RecordRetention
+	PollInterval          time.Duration // how often to poll MediaMTX for status transitions
+
+	AuthMode     auth.Mode     // AUTH_MODE: none|internal|jwt
+	UsersFile    string        // AUTH_USERS_FILE for internal mode
+	JWKSURL      string        // AUTH_JWKS_URL for jwt mode
+	JWTClaim     string        // AUTH_JWT_CLAIM carrying the permission list
+	JWKSRefresh  time.Duration // AUTH_JWKS_REFRESH_INTERVAL
+	PermCacheTTL time.Duration // AUTH_PERMISSION_CACHE_TTL
+
+	ClusterEnabled bool     // CLUSTER_ENABLED: run leader election against a shared SQL store
+	ClusterSelf    string   // CLUSTER_SELF: this replica's base URL, its cluster identity and /internal/apply target
+	ClusterPeers   []string // CLUSTER_PEERS: comma-separated base URLs of the other replicas, reported by GET /api/cluster
+	ClusterSecret  string   // CLUSTER_SECRET: shared secret required on POST /internal/apply
 }
 
 func loadConfig() *Config {
+	authMode, err := auth.ParseMode(env("AUTH_MODE", "none"))
+	if err != nil {
+		logger.Error("invalid config", "error", err)
+		os.Exit(1)
+	}
+
 	return &Config{
 		ListenAddr:  env("LISTEN_ADDR", ":8080"),
 		MediaMTXAPI: env("MEDIAMTX_API_URL", "http://localhost:9997"),
+		PlaybackAPI: env("MEDIAMTX_PLAYBACK_URL", "http://localhost:9996"),
+		Host:        env("MEDIAMTX_HOST", "localhost"),
 		WebRTCPort:  env("WEBRTC_PORT", "8889"),
 		HLSPort:     env("HLS_PORT", "8888"),
 		RTSPPort:    env("RTSP_PORT", "8554"),
+		RTMPPort:    env("RTMP_PORT", "1935"),
+		SRTPort:     env("SRT_PORT", "8890"),
 		StorePath:   env("STORE_PATH", "./data/streams.json"),
 		WebDir:      env("WEB_DIR", "./web"),
+
+		StoreDriver: env("STORE_DRIVER", "json"),
+		StoreDSN:    env("STORE_DSN", ""),
+
+		RecordPath:            env("RECORD_PATH", "./data/recordings/%path/%Y-%m-%d_%H-%M-%S-%f"),
+		RecordJanitorInterval: envDuration("RECORD_JANITOR_INTERVAL", 10*time.Minute),
+		PollInterval:          envDuration("POLL_INTERVAL", 2*time.Second),
+
+		AuthMode:     authMode,
+		UsersFile:    env("AUTH_USERS_FILE", "./data/users.yaml"),
+		JWKSURL:      env("AUTH_JWKS_URL", ""),
+		JWTClaim:     env("AUTH_JWT_CLAIM", "mediamtx_permissions"),
+		JWKSRefresh:  envDuration("AUTH_JWKS_REFRESH_INTERVAL", 5*time.Minute),
+		PermCacheTTL: envDuration("AUTH_PERMISSION_CACHE_TTL", 30*time.Second),
+
+		ClusterEnabled: envBool("CLUSTER_ENABLED", false),
+		ClusterSelf:    env("CLUSTER_SELF", ""),
+		ClusterPeers:   envList("CLUSTER_PEERS"),
+		ClusterSecret:  env("CLUSTER_SECRET", ""),
 	}
 }
 
@@ -48,109 +106,110 @@ func env(key, fallback string) string {
 	return fallback
 }
 
-// ============================================================
-// Models
-// ============================================================
-
-type Stream struct {
-	Name      string `json:"name"`
-	Label     string `json:"label"`
-	RTSPUrl   string `json:"rtspUrl"`
-	Status    string `json:"status"` // "connecting", "online", "offline"
-	CreatedAt string `json:"createdAt"`
-}
-
-type AddStreamRequest struct {
-	Name    string `json:"name"`    // optional, auto-generated if empty
-	Label   string `json:"label"`   // optional, friendly name
-	RTSPUrl string `json:"rtspUrl"` // required, RTSP source URL
-}
-
-type APIResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-}
-
-// ============================================================
-// Store — JSON file persistence
-// ============================================================
-
-type Store struct {
-	mu      sync.RWMutex
-	streams map[string]*Stream
-	path    string
-}
-
-func NewStore(path string) *Store {
-	s := &Store{
-		streams: make(map[string]*Stream),
-		path:    path,
+func envDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
 	}
-	s.load()
-	return s
-}
-
-func (s *Store) load() {
-	data, err := os.ReadFile(s.path)
+	d, err := time.ParseDuration(v)
 	if err != nil {
-		return
+		logger.Warn("invalid duration, using default", "key", key, "value", v, "default", fallback)
+		return fallback
 	}
-	var streams []*Stream
-	if err := json.Unmarshal(data, &streams); err != nil {
-		log.Printf("WARN: failed to parse store: %v", err)
-		return
+	return d
+}
+
+func envBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
 	}
-	for _, st := range streams {
-		s.streams[st.Name] = st
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		logger.Warn("invalid bool, using default", "key", key, "value", v, "default", fallback)
+		return fallback
 	}
-	log.Printf("Loaded %d streams from %s", len(s.streams), s.path)
+	return b
 }
 
-func (s *Store) save() {
-	s.mu.RLock()
-	streams := make([]*Stream, 0, len(s.streams))
-	for _, st := range s.streams {
-		streams = append(streams, st)
+// envList splits a comma-separated env var into its trimmed, non-empty
+// parts, or nil if unset.
+func envList(key string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
 	}
-	s.mu.RUnlock()
-
-	data, _ := json.MarshalIndent(streams, "", "  ")
-	dir := filepath.Dir(s.path)
-	os.MkdirAll(dir, 0755)
-	if err := os.WriteFile(s.path, data, 0644); err != nil {
-		log.Printf("WARN: failed to save store: %v", err)
+	var result []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
 	}
+	return result
 }
 
-func (s *Store) Add(stream *Stream) {
-	s.mu.Lock()
-	s.streams[stream.Name] = stream
-	s.mu.Unlock()
-	s.save()
+// newAuthenticator builds the Authenticator selected by cfg.AuthMode,
+// wrapping it with a permission cache unless caching is disabled.
+func newAuthenticator(cfg *Config) auth.Authenticator {
+	var authn auth.Authenticator
+	switch cfg.AuthMode {
+	case auth.ModeInternal:
+		a, err := auth.NewInternalAuthenticator(cfg.UsersFile)
+		if err != nil {
+			logger.Error("auth setup failed", "error", err)
+			os.Exit(1)
+		}
+		authn = a
+	case auth.ModeJWT:
+		if cfg.JWKSURL == "" {
+			logger.Error("AUTH_JWKS_URL is required when AUTH_MODE=jwt")
+			os.Exit(1)
+		}
+		a, err := auth.NewJWTAuthenticator(cfg.JWKSURL, cfg.JWTClaim, cfg.JWKSRefresh)
+		if err != nil {
+			logger.Error("auth setup failed", "error", err)
+			os.Exit(1)
+		}
+		authn = a
+	default:
+		authn = auth.NewNoneAuthenticator()
+	}
+	return auth.WithCache(authn, cfg.PermCacheTTL)
 }
 
-func (s *Store) Delete(name string) {
-	s.mu.Lock()
-	delete(s.streams, name)
-	s.mu.Unlock()
-	s.save()
-}
+// ============================================================
+// Models
+// ============================================================
 
-func (s *Store) Get(name string) *Stream {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.streams[name]
+type AddStreamRequest struct {
+	Name          string `json:"name"`      // optional, auto-generated if empty
+	Label         string `json:"label"`     // optional, friendly name
+	Kind          string `json:"kind"`      // optional hint: rtsp, rtmp, srt, udp, whep, publisher
+	SourceURL     string `json:"sourceUrl"` // source URL; omit (with kind=publisher) for client push
+	RTSPTransport string `json:"rtspTransport,omitempty"`
+	SRTStreamID   string `json:"srtStreamId,omitempty"`
+
+	RecordEnabled         bool          `json:"recordEnabled,omitempty"`
+	RecordFormat          string        `json:"recordFormat,omitempty"`          // "fmp4" (default) or "mpegts"
+	RecordSegmentDuration time.Duration `json:"recordSegmentDuration,omitempty"` // default 1h
+	RecordRetention       time.Duration `json:"recordRetention,omitempty"`       // 0 = keep forever
+}
+
+// PatchStreamRequest carries the mutable subset of a stream's fields for
+// PATCH /api/streams/{name}. Pointer fields distinguish "omitted" from
+// "set to the zero value", so e.g. {} leaves everything unchanged.
+type PatchStreamRequest struct {
+	Label                 *string        `json:"label,omitempty"`
+	RecordEnabled         *bool          `json:"recordEnabled,omitempty"`
+	RecordFormat          *string        `json:"recordFormat,omitempty"`
+	RecordSegmentDuration *time.Duration `json:"recordSegmentDuration,omitempty"`
+	RecordRetention       *time.Duration `json:"recordRetention,omitempty"`
 }
 
-func (s *Store) List() []*Stream {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	result := make([]*Stream, 0, len(s.streams))
-	for _, st := range s.streams {
-		result = append(result, st)
-	}
-	return result
+type APIResponse struct {
+	Success bool        `json:"success"`
+	Data    interface{} `json:"data,omitempty"`
+	Error   string      `json:"error,omitempty"`
 }
 
 // ============================================================
@@ -169,12 +228,21 @@ func NewMTXClient(apiURL string) *MTXClient {
 	}
 }
 
-// AddPath adds a new RTSP source path to MediaMTX
-func (c *MTXClient) AddPath(name, source string) error {
-	body, _ := json.Marshal(map[string]interface{}{
-		"source":         source,
+// AddPath adds a new path to MediaMTX. source is the resolved MediaMTX
+// "source" field value (empty for a path that only accepts client
+// publishes). extra carries any additional path fields a source kind
+// needs, e.g. {"rtspTransport": "tcp"}.
+func (c *MTXClient) AddPath(name, source string, extra map[string]interface{}) error {
+	fields := map[string]interface{}{
 		"sourceOnDemand": false,
-	})
+	}
+	if source != "" {
+		fields["source"] = source
+	}
+	for k, v := range extra {
+		fields[k] = v
+	}
+	body, _ := json.Marshal(fields)
 
 	url := fmt.Sprintf("%s/v3/config/paths/add/%s", c.apiURL, name)
 	req, _ := http.NewRequest("POST", url, bytes.NewReader(body))
@@ -213,12 +281,13 @@ func (c *MTXClient) DeletePath(name string) error {
 
 // PathInfo holds runtime path status from MediaMTX
 type PathInfo struct {
-	Name    string `json:"name"`
-	Ready   bool   `json:"ready"`
-	Source  *struct {
+	Name   string `json:"name"`
+	Ready  bool   `json:"ready"`
+	Source *struct {
 		Type string `json:"type"`
 	} `json:"source"`
-	Readers []interface{} `json:"readers"`
+	Readers   []interface{} `json:"readers"`
+	BytesSent int64         `json:"bytesSent"`
 }
 
 // ListPaths lists all active paths from MediaMTX
@@ -255,11 +324,49 @@ func (c *MTXClient) Ping() error {
 // ============================================================
 
 type Server struct {
-	cfg   *Config
-	store *Store
-	mtx   *MTXClient
+	cfg     *Config
+	store   store.Store
+	mtx     *MTXClient
+	authn   auth.Authenticator
+	metrics *metrics.Registry
+	events  *eventBus
+	elector *cluster.Elector // nil outside CLUSTER_ENABLED mode; this replica is then always its own leader
+
+	statusMu    sync.RWMutex
+	statusCache map[string]string // stream name -> last observed status
+	mtxUp       bool
+}
+
+// requireAuth wraps next so it only runs once s.authn grants action on the
+// path produced by pathOf(r). It writes a 401 when credentials are
+// missing/invalid and a 403 when they're valid but lack the permission.
+func (s *Server) requireAuth(action auth.Action, pathOf func(r *http.Request) string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := s.authn.AuthenticateRequest(r)
+		if err == auth.ErrForbidden {
+			jsonError(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			jsonError(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !id.Allowed(action, pathOf(r)) {
+			jsonError(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
 }
 
+// apiPath is the pathOf function for routes that act on the whole API
+// rather than a specific stream name.
+func apiPath(r *http.Request) string { return "*" }
+
+// streamPath extracts the {name} path value, used for routes scoped to a
+// single stream.
+func streamPath(r *http.Request) string { return r.PathValue("name") }
+
 var nameRegex = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
 
 func sanitizeName(name string) string {
@@ -280,13 +387,15 @@ func (s *Server) handleAddStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate RTSP URL
-	if req.RTSPUrl == "" {
-		jsonError(w, "rtspUrl is required", http.StatusBadRequest)
-		return
-	}
-	if !strings.HasPrefix(req.RTSPUrl, "rtsp://") && !strings.HasPrefix(req.RTSPUrl, "rtsps://") {
-		jsonError(w, "URL must start with rtsp:// or rtsps://", http.StatusBadRequest)
+	// Classify and resolve the source into MediaMTX path fields
+	resolved, err := sources.Detect(sources.Request{
+		Kind:          req.Kind,
+		SourceURL:     req.SourceURL,
+		SRTStreamID:   req.SRTStreamID,
+		RTSPTransport: req.RTSPTransport,
+	})
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -297,13 +406,33 @@ func (s *Server) handleAddStream(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check duplicate
-	if s.store.Get(name) != nil {
+	if _, err := s.store.Get(name); err == nil {
 		jsonError(w, fmt.Sprintf("Stream '%s' already exists", name), http.StatusConflict)
 		return
+	} else if err != store.ErrNotFound {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	mtxSource := resolved.Source
+	if resolved.Kind == sources.KindPublisher {
+		mtxSource = ""
+	}
+
+	recordFormat := req.RecordFormat
+	if recordFormat == "" {
+		recordFormat = "fmp4"
+	}
+	recordSegmentDuration := req.RecordSegmentDuration
+	if recordSegmentDuration <= 0 {
+		recordSegmentDuration = time.Hour
 	}
 
-	// Add to MediaMTX via Control API
-	if err := s.mtx.AddPath(name, req.RTSPUrl); err != nil {
+	extra := mergeFields(resolved.Extra, recordingFields(s.cfg, name, req.RecordEnabled, recordFormat, recordSegmentDuration, req.RecordRetention))
+
+	// Add to MediaMTX via Control API (or the cluster leader, if this
+	// replica isn't it)
+	if err := s.applyPath(name, mtxSource, extra); err != nil {
 		jsonError(w, err.Error(), http.StatusBadGateway)
 		return
 	}
@@ -313,37 +442,140 @@ func (s *Server) handleAddStream(w http.ResponseWriter, r *http.Request) {
 		label = name
 	}
 
-	stream := &Stream{
-		Name:      name,
-		Label:     label,
-		RTSPUrl:   req.RTSPUrl,
-		Status:    "connecting",
-		CreatedAt: time.Now().Format(time.RFC3339),
+	stream := &store.Stream{
+		Name:          name,
+		Label:         label,
+		Kind:          string(resolved.Kind),
+		SourceURL:     req.SourceURL,
+		RTSPTransport: req.RTSPTransport,
+		SRTStreamID:   req.SRTStreamID,
+		Status:        "connecting",
+		CreatedAt:     time.Now().Format(time.RFC3339),
+		IngressURLs: sources.IngressURLs(name, sources.Ports{
+			Host: s.cfg.Host,
+			RTSP: s.cfg.RTSPPort,
+			RTMP: s.cfg.RTMPPort,
+			SRT:  s.cfg.SRTPort,
+		}, req.SRTStreamID),
+		RecordEnabled:         req.RecordEnabled,
+		RecordFormat:          recordFormat,
+		RecordSegmentDuration: int64(recordSegmentDuration),
+		RecordRetention:       int64(req.RecordRetention),
+	}
+	if err := s.store.Add(stream); err != nil {
+		switch err {
+		case store.ErrConflict:
+			jsonError(w, fmt.Sprintf("Stream '%s' already exists", name), http.StatusConflict)
+		default:
+			jsonError(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
 	}
-	s.store.Add(stream)
+	s.events.publish(Event{Type: "stream.added", Data: stream})
 
-	log.Printf("Added stream: %s → %s", name, req.RTSPUrl)
+	logger.Info("added stream", "name", name, "kind", stream.Kind, "source", req.SourceURL)
 	jsonOK(w, stream)
 }
 
+// PATCH /api/streams/{name} — update a stream's mutable fields (its label
+// and recording config) and re-apply them to its MediaMTX path. Uses
+// store.Store's optimistic-concurrency Update so concurrent edits from
+// multiple API nodes don't clobber each other.
+func (s *Server) handlePatchStream(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	stream, err := s.store.Get(name)
+	if err == store.ErrNotFound {
+		jsonError(w, "Stream not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var req PatchStreamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonError(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Label != nil {
+		stream.Label = *req.Label
+	}
+	if req.RecordEnabled != nil {
+		stream.RecordEnabled = *req.RecordEnabled
+	}
+	if req.RecordFormat != nil {
+		stream.RecordFormat = *req.RecordFormat
+	}
+	if req.RecordSegmentDuration != nil {
+		stream.RecordSegmentDuration = int64(*req.RecordSegmentDuration)
+	}
+	if req.RecordRetention != nil {
+		stream.RecordRetention = int64(*req.RecordRetention)
+	}
+
+	resolved, err := sources.Detect(sources.Request{
+		Kind:          stream.Kind,
+		SourceURL:     stream.SourceURL,
+		SRTStreamID:   stream.SRTStreamID,
+		RTSPTransport: stream.RTSPTransport,
+	})
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	mtxSource := resolved.Source
+	if resolved.Kind == sources.KindPublisher {
+		mtxSource = ""
+	}
+	extra := mergeFields(resolved.Extra, recordingFields(s.cfg, name, stream.RecordEnabled, stream.RecordFormat,
+		time.Duration(stream.RecordSegmentDuration), time.Duration(stream.RecordRetention)))
+
+	if err := s.applyPath(name, mtxSource, extra); err != nil {
+		jsonError(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	if err := s.store.Update(stream); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			jsonError(w, "Stream not found", http.StatusNotFound)
+		case store.ErrConflict:
+			jsonError(w, "Stream was updated concurrently, retry", http.StatusConflict)
+		default:
+			jsonError(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	s.events.publish(Event{Type: "stream.updated", Data: stream})
+
+	logger.Info("updated stream", "name", name, "recordEnabled", stream.RecordEnabled)
+	jsonOK(w, stream)
+}
+
+// GET /api/sources/kinds — lists supported ingest kinds so the web UI can
+// render the right form fields for each one.
+func (s *Server) handleSourceKinds(w http.ResponseWriter, r *http.Request) {
+	jsonOK(w, sources.Kinds())
+}
+
 // GET /api/streams — list all streams with live status
 func (s *Server) handleListStreams(w http.ResponseWriter, r *http.Request) {
-	streams := s.store.List()
-
-	// Enrich with real-time status from MediaMTX
-	if paths, err := s.mtx.ListPaths(); err == nil {
-		for _, st := range streams {
-			if p, ok := paths[st.Name]; ok {
-				if p.Ready {
-					st.Status = "online"
-				} else {
-					st.Status = "connecting"
-				}
-			} else {
-				st.Status = "offline"
-			}
+	streams, err := s.store.List()
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Status comes from the background poll loop (see pollStatus), not a
+	// live MediaMTX call on every request.
+	s.statusMu.RLock()
+	for _, st := range streams {
+		if status, ok := s.statusCache[st.Name]; ok {
+			st.Status = status
 		}
 	}
+	s.statusMu.RUnlock()
 
 	jsonOK(w, streams)
 }
@@ -356,18 +588,30 @@ func (s *Server) handleDeleteStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if s.store.Get(name) == nil {
+	if _, err := s.store.Get(name); err != nil {
 		jsonError(w, "Stream not found", http.StatusNotFound)
 		return
 	}
 
-	// Remove from MediaMTX
-	if err := s.mtx.DeletePath(name); err != nil {
-		log.Printf("WARN: MediaMTX delete error: %v", err)
+	// Remove from MediaMTX (or ask the cluster leader to, if this replica
+	// isn't it)
+	if err := s.applyDelete(name); err != nil {
+		logger.Warn("MediaMTX delete error", "error", err)
 	}
 
-	s.store.Delete(name)
-	log.Printf("Deleted stream: %s", name)
+	if err := s.store.Delete(name); err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.statusMu.Lock()
+	delete(s.statusCache, name)
+	s.statusMu.Unlock()
+	if s.metrics != nil {
+		s.metrics.DeleteStream(name, streamStatuses)
+	}
+	s.events.publish(Event{Type: "stream.deleted", Data: map[string]string{"name": name}})
+
+	logger.Info("deleted stream", "name", name)
 	jsonOK(w, map[string]string{"deleted": name})
 }
 
@@ -397,20 +641,56 @@ func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// Restore streams from store to MediaMTX on startup
-func (s *Server) restoreStreams() {
-	streams := s.store.List()
-	restored := 0
-	for _, st := range streams {
-		if err := s.mtx.AddPath(st.Name, st.RTSPUrl); err != nil {
-			log.Printf("WARN: restore failed for %s: %v", st.Name, err)
-		} else {
-			restored++
-		}
+// mtxAuthRequest mirrors the body MediaMTX's authMethod=http POSTs for every
+// publish/read/playback attempt.
+type mtxAuthRequest struct {
+	IP       string `json:"ip"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	Path     string `json:"path"`
+	Protocol string `json:"protocol"`
+	ID       string `json:"id"`
+	Action   string `json:"action"`
+	Query    string `json:"query"`
+}
+
+// POST /api/auth/check — authorizes a MediaMTX publish/read/playback
+// attempt by credentials+action+path. MediaMTX treats any non-2xx response
+// as a denial.
+func (s *Server) handleAuthCheck(w http.ResponseWriter, r *http.Request) {
+	var req mtxAuthRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonError(w, "Invalid JSON body", http.StatusBadRequest)
+		return
 	}
-	if len(streams) > 0 {
-		log.Printf("Restored %d/%d streams to MediaMTX", restored, len(streams))
+
+	id, err := s.authn.AuthenticateCredentials(req.User, req.Password)
+	if err != nil {
+		jsonError(w, "unauthorized", http.StatusUnauthorized)
+		return
 	}
+	if !id.Allowed(auth.Action(req.Action), req.Path) {
+		jsonError(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	jsonOK(w, map[string]string{"subject": id.Subject})
+}
+
+// GET /api/auth/jwks — passthrough so MediaMTX (and any other verifier) can
+// fetch the same JWKS document stream-server cached for AUTH_MODE=jwt.
+func (s *Server) handleAuthJWKS(w http.ResponseWriter, r *http.Request) {
+	jwtAuth, ok := auth.Unwrap(s.authn).(*auth.JWTAuthenticator)
+	if !ok {
+		jsonError(w, "JWKS is only available when AUTH_MODE=jwt", http.StatusNotFound)
+		return
+	}
+	raw, err := jwtAuth.JWKS()
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(raw)
 }
 
 // ============================================================
@@ -445,47 +725,127 @@ func corsMiddleware(next http.Handler) http.Handler {
 // Main
 // ============================================================
 
+// storeDSN resolves the connection string passed to store.New: STORE_DSN if
+// set, otherwise the legacy STORE_PATH for the default "json" driver.
+func storeDSN(cfg *Config) string {
+	if cfg.StoreDSN != "" {
+		return cfg.StoreDSN
+	}
+	return cfg.StorePath
+}
+
 func main() {
+	migrateFromJSON := flag.String("migrate-from-json", "", "one-shot: import streams from this legacy JSON store file into STORE_DRIVER/STORE_DSN, then exit")
+	flag.Parse()
+
 	cfg := loadConfig()
-	store := NewStore(cfg.StorePath)
+
+	db, err := store.New(cfg.StoreDriver, storeDSN(cfg))
+	if err != nil {
+		logger.Error("store setup failed", "error", err)
+		os.Exit(1)
+	}
+
+	if *migrateFromJSON != "" {
+		imported, skipped, err := store.MigrateFromJSON(*migrateFromJSON, db)
+		if err != nil {
+			logger.Error("migrate-from-json failed", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("migrate-from-json complete", "imported", imported, "skipped", skipped)
+		return
+	}
+
 	mtx := NewMTXClient(cfg.MediaMTXAPI)
 
-	srv := &Server{cfg: cfg, store: store, mtx: mtx}
-
-	// Wait for MediaMTX then restore saved streams
-	go func() {
-		for i := 0; i < 30; i++ {
-			if err := mtx.Ping(); err == nil {
-				log.Println("MediaMTX API is reachable")
-				srv.restoreStreams()
-				return
-			}
-			time.Sleep(2 * time.Second)
+	var elector *cluster.Elector
+	if cfg.ClusterEnabled {
+		if cfg.ClusterSelf == "" {
+			logger.Error("CLUSTER_SELF is required when CLUSTER_ENABLED=true")
+			os.Exit(1)
+		}
+		sqlDB, ph, err := store.DB(db)
+		if err != nil {
+			logger.Error("cluster setup failed", "error", err)
+			os.Exit(1)
+		}
+		elector, err = cluster.NewElector(sqlDB, ph, cfg.ClusterSelf, cfg.ClusterPeers)
+		if err != nil {
+			logger.Error("cluster setup failed", "error", err)
+			os.Exit(1)
 		}
-		log.Println("WARN: MediaMTX not reachable after 60s")
-	}()
+	}
+
+	reg := metrics.NewRegistry()
+	srv := &Server{
+		cfg:         cfg,
+		store:       db,
+		mtx:         mtx,
+		authn:       newAuthenticator(cfg),
+		metrics:     reg,
+		events:      newEventBus(),
+		elector:     elector,
+		statusCache: make(map[string]string),
+	}
+	logger.Info("auth mode configured", "mode", cfg.AuthMode)
+	if elector != nil {
+		logger.Info("cluster mode enabled", "self", cfg.ClusterSelf, "peers", cfg.ClusterPeers)
+		go srv.runClusterElection()
+	}
+
+	go srv.runRetentionJanitor()
+	go srv.pollStatus(cfg.PollInterval)
+	go srv.runReconciler(cluster.RenewInterval)
 
 	mux := http.NewServeMux()
 
-	// API routes (Go 1.22+ method routing)
-	mux.HandleFunc("POST /api/streams", srv.handleAddStream)
-	mux.HandleFunc("GET /api/streams", srv.handleListStreams)
-	mux.HandleFunc("DELETE /api/streams/{name}", srv.handleDeleteStream)
-	mux.HandleFunc("GET /api/health", srv.handleHealth)
-	mux.HandleFunc("GET /api/config", srv.handleConfig)
+	// route wires a handler behind both the Authenticator and the
+	// Prometheus instrumentation middleware, labeled by its mux pattern.
+	route := func(pattern string, action auth.Action, pathOf func(r *http.Request) string, handler http.HandlerFunc) {
+		mux.HandleFunc(pattern, reg.Instrument(pattern, srv.requireAuth(action, pathOf, handler)))
+	}
+
+	// API routes (Go 1.22+ method routing), each guarded by the configured
+	// Authenticator and instrumented for Prometheus.
+	route("POST /api/streams", auth.ActionAPI, apiPath, srv.handleAddStream)
+	route("GET /api/streams", auth.ActionAPI, apiPath, srv.handleListStreams)
+	route("PATCH /api/streams/{name}", auth.ActionAPI, streamPath, srv.handlePatchStream)
+	route("DELETE /api/streams/{name}", auth.ActionAPI, streamPath, srv.handleDeleteStream)
+	route("GET /api/health", auth.ActionAPI, apiPath, srv.handleHealth)
+	route("GET /api/config", auth.ActionAPI, apiPath, srv.handleConfig)
+	route("GET /api/sources/kinds", auth.ActionAPI, apiPath, srv.handleSourceKinds)
+	route("GET /api/streams/{name}/recordings", auth.ActionAPI, streamPath, srv.handleListRecordings)
+	route("GET /api/streams/{name}/recordings/{start}/download", auth.ActionAPI, streamPath, srv.handleDownloadRecording)
+	route("GET /api/streams/{name}/playback", auth.ActionAPI, streamPath, srv.handlePlayback)
+	route("GET /api/events", auth.ActionAPI, apiPath, srv.handleEvents)
+	route("GET /api/cluster", auth.ActionAPI, apiPath, srv.handleCluster)
+
+	// MediaMTX authMethod=http consults these directly; they are not
+	// themselves gated behind requireAuth since they ARE the auth check.
+	mux.HandleFunc("POST /api/auth/check", srv.handleAuthCheck)
+	mux.HandleFunc("GET /api/auth/jwks", srv.handleAuthJWKS)
+
+	// /internal/apply is replica-to-replica only, authenticated by
+	// CLUSTER_SECRET rather than the end-user Authenticator.
+	mux.HandleFunc("POST /internal/apply", srv.handleInternalApply)
+
+	// The metrics endpoint is unauthenticated,
+	// mirroring MediaMTX's own metrics/pprof split (internal-only in
+	// practice, behind network policy rather than application auth).
+	mux.Handle("GET /metrics", reg.Handler())
 
 	// Serve static web UI
 	mux.Handle("/", http.FileServer(http.Dir(cfg.WebDir)))
 
 	handler := corsMiddleware(mux)
 
-	log.Println("============================================")
-	log.Printf("  🚀 Stream API Server")
-	log.Printf("  Listen:      %s", cfg.ListenAddr)
-	log.Printf("  MediaMTX:    %s", cfg.MediaMTXAPI)
-	log.Printf("  Web UI:      http://localhost%s", cfg.ListenAddr)
-	log.Printf("  API:         http://localhost%s/api/streams", cfg.ListenAddr)
-	log.Println("============================================")
+	logger.Info("starting stream API server",
+		"listen", cfg.ListenAddr,
+		"mediamtx", cfg.MediaMTXAPI,
+		"webUI", fmt.Sprintf("http://localhost%s", cfg.ListenAddr),
+		"api", fmt.Sprintf("http://localhost%s/api/streams", cfg.ListenAddr),
+	)
 
-	log.Fatal(http.ListenAndServe(cfg.ListenAddr, handler))
+	logger.Error("server stopped", "error", http.ListenAndServe(cfg.ListenAddr, handler))
+	os.Exit(1)
 }
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// mergeFields combines any number of MediaMTX path field maps, later maps
+// winning on key conflicts. nil maps are ignored.
+func mergeFields(maps ...map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	for _, m := range maps {
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// recordingFields builds the MediaMTX path fields that turn disk recording
+// on or off for a path.
+func recordingFields(cfg *Config, name string, enabled bool, format string, segmentDuration, retention time.Duration) map[string]interface{} {
+	if !enabled {
+		return map[string]interface{}{"record": false}
+	}
+	fields := map[string]interface{}{
+		"record":                true,
+		"recordPath":            cfg.RecordPath,
+		"recordFormat":          format,
+		"recordSegmentDuration": segmentDuration.String(),
+	}
+	if retention > 0 {
+		fields["recordDeleteAfter"] = retention.String()
+	}
+	return fields
+}
+
+// RecordingSegment mirrors one entry of MediaMTX's
+// GET /v3/recordings/list/{name} response.
+type RecordingSegment struct {
+	Start    string `json:"start"`
+	Duration string `json:"duration"`
+}
+
+// ListRecordings fetches the recorded segments for path name from MediaMTX.
+func (c *MTXClient) ListRecordings(name string) ([]RecordingSegment, error) {
+	reqURL := fmt.Sprintf("%s/v3/recordings/list/%s", c.apiURL, url.PathEscape(name))
+	resp, err := c.client.Get(reqURL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach MediaMTX API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("MediaMTX error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Segments []RecordingSegment `json:"segments"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Segments, nil
+}
+
+// DeleteRecording deletes one recorded segment, identified by its RFC3339
+// start time, from MediaMTX.
+func (c *MTXClient) DeleteRecording(name, start string) error {
+	reqURL := fmt.Sprintf("%s/v3/recordings/deletesegment?path=%s&start=%s",
+		c.apiURL, url.QueryEscape(name), url.QueryEscape(start))
+	req, _ := http.NewRequest("DELETE", reqURL, nil)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot reach MediaMTX API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 && resp.StatusCode != 404 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("MediaMTX error %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// GET /api/streams/{name}/recordings — list recorded segments for a stream.
+func (s *Server) handleListRecordings(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, err := s.store.Get(name); err != nil {
+		jsonError(w, "Stream not found", http.StatusNotFound)
+		return
+	}
+
+	segments, err := s.mtx.ListRecordings(name)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	jsonOK(w, segments)
+}
+
+// GET /api/streams/{name}/recordings/{start}/download — streams one
+// recorded mp4 segment by proxying MediaMTX's playback server.
+func (s *Server) handleDownloadRecording(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	start := r.PathValue("start")
+	if _, err := s.store.Get(name); err != nil {
+		jsonError(w, "Stream not found", http.StatusNotFound)
+		return
+	}
+
+	// MediaMTX's playback /get requires a duration, so look up the
+	// segment's own length rather than sending an empty one.
+	duration := ""
+	segments, err := s.mtx.ListRecordings(name)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	for _, seg := range segments {
+		if seg.Start == start {
+			duration = seg.Duration
+			break
+		}
+	}
+	if duration == "" {
+		jsonError(w, "recording not found", http.StatusNotFound)
+		return
+	}
+
+	s.proxyPlayback(w, r, name, start, duration)
+}
+
+// GET /api/streams/{name}/playback?start=RFC3339&duration=... — proxies
+// MediaMTX's playback server for a caller-chosen time range.
+func (s *Server) handlePlayback(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, err := s.store.Get(name); err != nil {
+		jsonError(w, "Stream not found", http.StatusNotFound)
+		return
+	}
+
+	start := r.URL.Query().Get("start")
+	if start == "" {
+		jsonError(w, "start is required (RFC3339)", http.StatusBadRequest)
+		return
+	}
+	duration := r.URL.Query().Get("duration")
+	s.proxyPlayback(w, r, name, start, duration)
+}
+
+// proxyPlayback forwards a request to MediaMTX's playback server
+// (GET /get?path=...&start=...&duration=...) and streams its response body
+// back unchanged.
+func (s *Server) proxyPlayback(w http.ResponseWriter, r *http.Request, name, start, duration string) {
+	values := url.Values{"path": {name}, "start": {start}}
+	if duration != "" {
+		values.Set("duration", duration)
+	}
+	reqURL := fmt.Sprintf("%s/get?%s", s.cfg.PlaybackAPI, values.Encode())
+
+	resp, err := s.mtx.client.Get(reqURL)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("cannot reach MediaMTX playback server: %v", err), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		jsonError(w, "recording not found", http.StatusNotFound)
+		return
+	}
+
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	if cl := resp.Header.Get("Content-Length"); cl != "" {
+		w.Header().Set("Content-Length", cl)
+	}
+	io.Copy(w, resp.Body)
+}
+
+// runRetentionJanitor deletes recorded segments older than each stream's
+// RecordRetention, on every tick of cfg.RecordJanitorInterval. It blocks and
+// should be run in its own goroutine.
+func (s *Server) runRetentionJanitor() {
+	ticker := time.NewTicker(s.cfg.RecordJanitorInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.enforceRetention()
+	}
+}
+
+func (s *Server) enforceRetention() {
+	streams, err := s.store.List()
+	if err != nil {
+		logger.Warn("janitor: cannot list streams", "error", err)
+		return
+	}
+	for _, st := range streams {
+		if !st.RecordEnabled || st.RecordRetention <= 0 {
+			continue
+		}
+		segments, err := s.mtx.ListRecordings(st.Name)
+		if err != nil {
+			logger.Warn("janitor: cannot list recordings", "name", st.Name, "error", err)
+			continue
+		}
+
+		cutoff := time.Now().Add(-time.Duration(st.RecordRetention))
+		for _, seg := range segments {
+			startTime, err := time.Parse(time.RFC3339, seg.Start)
+			if err != nil || startTime.After(cutoff) {
+				continue
+			}
+			if err := s.mtx.DeleteRecording(st.Name, seg.Start); err != nil {
+				logger.Warn("janitor: cannot delete recording", "name", st.Name, "start", seg.Start, "error", err)
+				continue
+			}
+			logger.Info("janitor: deleted expired recording", "name", st.Name, "start", seg.Start)
+		}
+	}
+}
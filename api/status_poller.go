@@ -0,0 +1,93 @@
+package main
+
+import "time"
+
+// streamStatuses enumerates the mutually-exclusive values SetStreamStatus
+// (and DeleteStream) track per stream.
+var streamStatuses = []string{"connecting", "online", "offline"}
+
+// pollStatus polls MediaMTX every interval to detect stream online/offline
+// transitions and MediaMTX reachability changes. It replaces the old lazy
+// ListPaths() call inside handleListStreams with a single shared loop that
+// also drives /api/events and the Prometheus gauges. It blocks and should
+// run in its own goroutine.
+func (s *Server) pollStatus(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.refreshStatus()
+	}
+}
+
+func (s *Server) refreshStatus() {
+	paths, err := s.mtx.ListPaths()
+	up := err == nil
+
+	s.statusMu.Lock()
+	wasUp := s.mtxUp
+	s.mtxUp = up
+	s.statusMu.Unlock()
+
+	if up != wasUp {
+		if up {
+			s.events.publish(Event{Type: "mediamtx.up"})
+		} else {
+			s.events.publish(Event{Type: "mediamtx.down"})
+		}
+	}
+	if s.metrics != nil {
+		s.metrics.MediaMTXUp.Set(boolToFloat(up))
+	}
+	if !up {
+		return
+	}
+
+	streams, err := s.store.List()
+	if err != nil {
+		logger.Warn("status poll: cannot list streams", "error", err)
+		return
+	}
+	for _, st := range streams {
+		status := "offline"
+		if p, ok := paths[st.Name]; ok {
+			if p.Ready {
+				status = "online"
+			} else {
+				status = "connecting"
+			}
+			if s.metrics != nil {
+				s.metrics.PathReaders.WithLabelValues(st.Name).Set(float64(len(p.Readers)))
+				s.metrics.PathBytesSent.WithLabelValues(st.Name).Set(float64(p.BytesSent))
+			}
+		}
+
+		s.statusMu.Lock()
+		prev, known := s.statusCache[st.Name]
+		s.statusCache[st.Name] = status
+		s.statusMu.Unlock()
+
+		if s.metrics != nil {
+			s.metrics.SetStreamStatus(st.Name, streamStatuses, status)
+		}
+
+		if known && prev != status {
+			switch status {
+			case "online":
+				s.events.publish(Event{Type: "stream.online", Data: map[string]string{"name": st.Name}})
+			case "offline":
+				s.events.publish(Event{Type: "stream.offline", Data: map[string]string{"name": st.Name}})
+			}
+		}
+	}
+
+	if s.metrics != nil {
+		s.metrics.StreamsTotal.Set(float64(len(streams)))
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
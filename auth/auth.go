@@ -0,0 +1,145 @@
+// Package auth implements pluggable authentication/authorization for the
+// stream-server API and for MediaMTX's "http" authMethod callback. It
+// supports three modes selected by AUTH_MODE: "none" (current behavior),
+// "internal" (users file + bcrypt + glob permissions), and "jwt" (bearer
+// tokens verified against a JWKS endpoint).
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Mode selects which backend guards the API and media plane.
+type Mode string
+
+const (
+	ModeNone     Mode = "none"
+	ModeInternal Mode = "internal"
+	ModeJWT      Mode = "jwt"
+)
+
+// ParseMode validates a raw AUTH_MODE value, defaulting to ModeNone.
+func ParseMode(raw string) (Mode, error) {
+	switch Mode(strings.ToLower(strings.TrimSpace(raw))) {
+	case "", ModeNone:
+		return ModeNone, nil
+	case ModeInternal:
+		return ModeInternal, nil
+	case ModeJWT:
+		return ModeJWT, nil
+	default:
+		return "", errors.New("unknown AUTH_MODE: " + raw)
+	}
+}
+
+// Action is a permission action matched against incoming requests, mirroring
+// MediaMTX's own publish/read/api split.
+type Action string
+
+const (
+	ActionPublish Action = "publish"
+	ActionRead    Action = "read"
+	ActionAPI     Action = "api"
+)
+
+// Permission grants an Action over any path matching one of Paths. Paths are
+// shell-style globs (path.Match), e.g. "cam-*".
+type Permission struct {
+	Action Action   `json:"action" yaml:"action"`
+	Paths  []string `json:"paths" yaml:"paths"`
+}
+
+// Allows reports whether this permission covers action on pathName.
+func (p Permission) Allows(action Action, pathName string) bool {
+	if p.Action != action {
+		return false
+	}
+	for _, pattern := range p.Paths {
+		if ok, _ := path.Match(pattern, pathName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Identity is the authenticated subject for a request, carrying its
+// permission set regardless of which Mode produced it.
+type Identity struct {
+	Subject     string
+	Permissions []Permission
+}
+
+// Allowed reports whether the identity may perform action on pathName.
+func (id *Identity) Allowed(action Action, pathName string) bool {
+	if id == nil {
+		return false
+	}
+	for _, p := range id.Permissions {
+		if p.Allows(action, pathName) {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrUnauthorized means no valid credentials were presented.
+var ErrUnauthorized = errors.New("auth: missing or invalid credentials")
+
+// ErrForbidden means the credentials were valid but lack the permission.
+var ErrForbidden = errors.New("auth: permission denied")
+
+// Authenticator verifies API requests and MediaMTX "http" authMethod
+// callbacks, returning the resulting Identity.
+type Authenticator interface {
+	// Mode reports which backend this Authenticator implements.
+	Mode() Mode
+	// AuthenticateRequest resolves the Identity for an incoming API
+	// request, reading its Authorization header. It returns
+	// ErrUnauthorized if no usable credentials are present.
+	AuthenticateRequest(r *http.Request) (*Identity, error)
+	// AuthenticateCredentials resolves the Identity for a MediaMTX
+	// authMethod=http callback, which may present either a
+	// username/password pair or a bearer token (used as password with an
+	// empty or "jwt" username, matching MediaMTX's convention).
+	AuthenticateCredentials(username, password string) (*Identity, error)
+}
+
+// noneAuthenticator accepts every request without checking credentials,
+// preserving the server's behavior before auth existed. API requests get
+// api, publish and read on every path; MediaMTX callbacks get publish and
+// read on every path, so any other callback action is denied.
+type noneAuthenticator struct{}
+
+// NewNoneAuthenticator returns an Authenticator that checks no credentials.
+func NewNoneAuthenticator() Authenticator { return noneAuthenticator{} }
+
+func (noneAuthenticator) Mode() Mode { return ModeNone }
+
+func (noneAuthenticator) AuthenticateRequest(r *http.Request) (*Identity, error) {
+	return &Identity{Subject: "anonymous", Permissions: []Permission{
+		{Action: ActionAPI, Paths: []string{"*"}},
+		{Action: ActionPublish, Paths: []string{"*"}},
+		{Action: ActionRead, Paths: []string{"*"}},
+	}}, nil
+}
+
+func (noneAuthenticator) AuthenticateCredentials(username, password string) (*Identity, error) {
+	return &Identity{Subject: "anonymous", Permissions: []Permission{
+		{Action: ActionPublish, Paths: []string{"*"}},
+		{Action: ActionRead, Paths: []string{"*"}},
+	}}, nil
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>"
+// header, returning "" if absent or malformed.
+func bearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if !strings.HasPrefix(h, prefix) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(h, prefix))
+}
@@ -0,0 +1,121 @@
+package auth
+
+import "testing"
+
+func TestPermissionAllows(t *testing.T) {
+	tests := []struct {
+		name   string
+		perm   Permission
+		action Action
+		path   string
+		want   bool
+	}{
+		{
+			name:   "exact match",
+			perm:   Permission{Action: ActionRead, Paths: []string{"cam-1"}},
+			action: ActionRead,
+			path:   "cam-1",
+			want:   true,
+		},
+		{
+			name:   "glob match",
+			perm:   Permission{Action: ActionRead, Paths: []string{"cam-*"}},
+			action: ActionRead,
+			path:   "cam-42",
+			want:   true,
+		},
+		{
+			name:   "glob does not cross path separator",
+			perm:   Permission{Action: ActionRead, Paths: []string{"cam-*"}},
+			action: ActionRead,
+			path:   "cam-1/sub",
+			want:   false,
+		},
+		{
+			name:   "wildcard matches everything at one segment",
+			perm:   Permission{Action: ActionPublish, Paths: []string{"*"}},
+			action: ActionPublish,
+			path:   "anything",
+			want:   true,
+		},
+		{
+			name:   "no pattern matches",
+			perm:   Permission{Action: ActionRead, Paths: []string{"cam-*", "lobby"}},
+			action: ActionRead,
+			path:   "office",
+			want:   false,
+		},
+		{
+			name:   "action mismatch even with matching path",
+			perm:   Permission{Action: ActionRead, Paths: []string{"cam-1"}},
+			action: ActionPublish,
+			path:   "cam-1",
+			want:   false,
+		},
+		{
+			name:   "empty paths never match",
+			perm:   Permission{Action: ActionRead, Paths: nil},
+			action: ActionRead,
+			path:   "cam-1",
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.perm.Allows(tt.action, tt.path); got != tt.want {
+				t.Errorf("Allows(%q, %q) = %v, want %v", tt.action, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentityAllowed(t *testing.T) {
+	id := &Identity{
+		Subject: "alice",
+		Permissions: []Permission{
+			{Action: ActionRead, Paths: []string{"cam-*"}},
+			{Action: ActionPublish, Paths: []string{"cam-1"}},
+		},
+	}
+
+	if !id.Allowed(ActionRead, "cam-7") {
+		t.Error("expected cam-7 to be readable")
+	}
+	if id.Allowed(ActionPublish, "cam-7") {
+		t.Error("did not expect cam-7 to be publishable")
+	}
+	if !id.Allowed(ActionPublish, "cam-1") {
+		t.Error("expected cam-1 to be publishable")
+	}
+
+	var nilID *Identity
+	if nilID.Allowed(ActionRead, "cam-1") {
+		t.Error("nil identity must never be allowed")
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    Mode
+		wantErr bool
+	}{
+		{"", ModeNone, false},
+		{"none", ModeNone, false},
+		{" JWT ", ModeJWT, false},
+		{"Internal", ModeInternal, false},
+		{"bogus", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMode(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMode(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMode(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// cachedAuthenticator wraps an Authenticator and memoizes the Identity for a
+// given credential for ttl, avoiding re-hashing passwords with bcrypt or
+// re-verifying JWT signatures on every request.
+type cachedAuthenticator struct {
+	inner Authenticator
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	identity *Identity
+	expires  time.Time
+}
+
+// WithCache wraps inner so that successful authentications are remembered
+// for ttl, keyed by a hash of the presented credential.
+func WithCache(inner Authenticator, ttl time.Duration) Authenticator {
+	if ttl <= 0 {
+		return inner
+	}
+	return &cachedAuthenticator{inner: inner, ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+func (c *cachedAuthenticator) Mode() Mode { return c.inner.Mode() }
+
+func (c *cachedAuthenticator) AuthenticateRequest(r *http.Request) (*Identity, error) {
+	key := credentialKey(r.Header.Get("Authorization"))
+	if id, ok := c.lookup(key); ok {
+		return id, nil
+	}
+	id, err := c.inner.AuthenticateRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, id)
+	return id, nil
+}
+
+func (c *cachedAuthenticator) AuthenticateCredentials(username, password string) (*Identity, error) {
+	key := credentialKey(username + ":" + password)
+	if id, ok := c.lookup(key); ok {
+		return id, nil
+	}
+	id, err := c.inner.AuthenticateCredentials(username, password)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, id)
+	return id, nil
+}
+
+func (c *cachedAuthenticator) lookup(key string) (*Identity, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.identity, true
+}
+
+func (c *cachedAuthenticator) store(key string, id *Identity) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{identity: id, expires: time.Now().Add(c.ttl)}
+}
+
+// credentialKey hashes a credential so raw passwords/tokens never sit in
+// memory as map keys.
+func credentialKey(credential string) string {
+	sum := sha256.Sum256([]byte(credential))
+	return hex.EncodeToString(sum[:])
+}
+
+// Unwrap returns the Authenticator wrapped by WithCache, or a unchanged if
+// it isn't a cached one. Useful for callers that need to type-assert down
+// to a concrete backend (e.g. JWTAuthenticator.JWKS).
+func Unwrap(a Authenticator) Authenticator {
+	if c, ok := a.(*cachedAuthenticator); ok {
+		return c.inner
+	}
+	return a
+}
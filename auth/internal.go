@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// internalUser is one entry of the users file: a username, a bcrypt hash of
+// its password, and the permissions it carries.
+type internalUser struct {
+	Username     string       `json:"username" yaml:"username"`
+	PasswordHash string       `json:"passwordHash" yaml:"passwordHash"`
+	Permissions  []Permission `json:"permissions" yaml:"permissions"`
+}
+
+type usersFile struct {
+	Users []internalUser `json:"users" yaml:"users"`
+}
+
+// InternalAuthenticator checks credentials against a users.yaml/json file
+// mapping username to a bcrypt password hash and a permission list.
+type InternalAuthenticator struct {
+	mu    sync.RWMutex
+	users map[string]internalUser
+}
+
+// NewInternalAuthenticator loads usersPath (YAML or JSON, detected by
+// extension) and returns an Authenticator backed by it.
+func NewInternalAuthenticator(usersPath string) (*InternalAuthenticator, error) {
+	a := &InternalAuthenticator{users: make(map[string]internalUser)}
+	if err := a.reload(usersPath); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *InternalAuthenticator) reload(usersPath string) error {
+	data, err := os.ReadFile(usersPath)
+	if err != nil {
+		return fmt.Errorf("auth: cannot read users file: %w", err)
+	}
+
+	var uf usersFile
+	switch strings.ToLower(filepath.Ext(usersPath)) {
+	case ".json":
+		err = json.Unmarshal(data, &uf)
+	default:
+		err = yaml.Unmarshal(data, &uf)
+	}
+	if err != nil {
+		return fmt.Errorf("auth: cannot parse users file: %w", err)
+	}
+
+	users := make(map[string]internalUser, len(uf.Users))
+	for _, u := range uf.Users {
+		users[u.Username] = u
+	}
+
+	a.mu.Lock()
+	a.users = users
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *InternalAuthenticator) Mode() Mode { return ModeInternal }
+
+// AuthenticateRequest reads HTTP Basic credentials from r and validates
+// them against the users file.
+func (a *InternalAuthenticator) AuthenticateRequest(r *http.Request) (*Identity, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+	return a.AuthenticateCredentials(username, password)
+}
+
+// AuthenticateCredentials verifies username/password against the loaded
+// users file and returns the matching Identity.
+func (a *InternalAuthenticator) AuthenticateCredentials(username, password string) (*Identity, error) {
+	a.mu.RLock()
+	u, ok := a.users[username]
+	a.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrUnauthorized
+	}
+	return &Identity{Subject: u.Username, Permissions: u.Permissions}, nil
+}
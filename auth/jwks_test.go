@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func generateTestJWKS(t *testing.T, kid string) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	set := jwkSet{Keys: []jwk{{
+		Kty: "RSA",
+		Kid: kid,
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big64(key.PublicKey.E)),
+	}}}
+	raw, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("marshal jwks: %v", err)
+	}
+	return key, raw
+}
+
+// big64 encodes an RSA public exponent the same way real JWKS documents do:
+// the minimal big-endian byte representation (no leading zero byte), e.g.
+// 65537 -> {0x01, 0x00, 0x01}.
+func big64(e int) []byte {
+	b := []byte{byte(e >> 16), byte(e >> 8), byte(e)}
+	for len(b) > 1 && b[0] == 0 {
+		b = b[1:]
+	}
+	return b
+}
+
+func TestKeyfuncFromJWKSValidToken(t *testing.T) {
+	key, raw := generateTestJWKS(t, "key-1")
+	keyFunc, err := keyfuncFromJWKS(raw)
+	if err != nil {
+		t.Fatalf("keyfuncFromJWKS: %v", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": "alice",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = "key-1"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	claims := jwt.MapClaims{}
+	parsed, err := jwt.ParseWithClaims(signed, claims, keyFunc)
+	if err != nil || !parsed.Valid {
+		t.Fatalf("expected valid token, got error=%v valid=%v", err, parsed.Valid)
+	}
+	if claims["sub"] != "alice" {
+		t.Errorf("sub = %v, want alice", claims["sub"])
+	}
+}
+
+func TestKeyfuncFromJWKSUnknownKid(t *testing.T) {
+	key, raw := generateTestJWKS(t, "key-1")
+	keyFunc, err := keyfuncFromJWKS(raw)
+	if err != nil {
+		t.Fatalf("keyfuncFromJWKS: %v", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "alice"})
+	token.Header["kid"] = "key-unknown"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	if _, err := jwt.ParseWithClaims(signed, jwt.MapClaims{}, keyFunc); err == nil {
+		t.Error("expected error for unknown kid, got nil")
+	}
+}
+
+// TestKeyfuncFromJWKSRejectsAlgorithmConfusion guards against the classic
+// RS256->HS256 downgrade attack, where an attacker signs a token with HMAC
+// using public key material (here the published JWKS document) as the
+// secret, hoping a naive verifier
+// will treat the "alg" header as trusted input.
+func TestKeyfuncFromJWKSRejectsAlgorithmConfusion(t *testing.T) {
+	_, raw := generateTestJWKS(t, "key-1")
+	keyFunc, err := keyfuncFromJWKS(raw)
+	if err != nil {
+		t.Fatalf("keyfuncFromJWKS: %v", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "attacker"})
+	token.Header["kid"] = "key-1"
+	signed, err := token.SignedString([]byte(raw))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	if _, err := jwt.ParseWithClaims(signed, jwt.MapClaims{}, keyFunc); err == nil {
+		t.Error("expected HS256-signed token to be rejected, got nil error")
+	}
+}
+
+func TestKeyfuncFromJWKSNoRSAKeys(t *testing.T) {
+	set := jwkSet{Keys: []jwk{{Kty: "EC", Kid: "key-1"}}}
+	raw, _ := json.Marshal(set)
+	if _, err := keyfuncFromJWKS(raw); err == nil {
+		t.Error("expected error when JWKS has no usable RSA keys, got nil")
+	}
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTAuthenticator verifies bearer tokens against keys published by a JWKS
+// URL, refreshing the key set periodically, and extracts a configurable
+// claim that carries the same []Permission shape as the internal backend.
+type JWTAuthenticator struct {
+	jwksURL   string
+	claimName string
+	client    *http.Client
+
+	mu       sync.RWMutex
+	keyFunc  jwt.Keyfunc
+	rawJWKS  []byte
+	fetchErr error
+}
+
+// NewJWTAuthenticator builds a JWTAuthenticator that fetches jwksURL
+// immediately and every refreshEvery thereafter. claimName names the JWT
+// claim holding the permission list (defaults to "mediamtx_permissions").
+func NewJWTAuthenticator(jwksURL, claimName string, refreshEvery time.Duration) (*JWTAuthenticator, error) {
+	if claimName == "" {
+		claimName = "mediamtx_permissions"
+	}
+	a := &JWTAuthenticator{
+		jwksURL:   jwksURL,
+		claimName: claimName,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+	if err := a.refresh(); err != nil {
+		return nil, err
+	}
+	if refreshEvery <= 0 {
+		refreshEvery = 5 * time.Minute
+	}
+	go a.refreshLoop(refreshEvery)
+	return a, nil
+}
+
+func (a *JWTAuthenticator) refreshLoop(every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := a.refresh(); err != nil {
+			a.mu.Lock()
+			a.fetchErr = err
+			a.mu.Unlock()
+		}
+	}
+}
+
+func (a *JWTAuthenticator) refresh() error {
+	resp, err := a.client.Get(a.jwksURL)
+	if err != nil {
+		return fmt.Errorf("auth: cannot fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("auth: cannot read JWKS response: %w", err)
+	}
+
+	keyFunc, err := keyfuncFromJWKS(raw)
+	if err != nil {
+		return fmt.Errorf("auth: invalid JWKS: %w", err)
+	}
+
+	a.mu.Lock()
+	a.rawJWKS = raw
+	a.keyFunc = keyFunc
+	a.fetchErr = nil
+	a.mu.Unlock()
+	return nil
+}
+
+// JWKS returns the last successfully fetched JWKS document verbatim, for the
+// /api/auth/jwks passthrough MediaMTX consults.
+func (a *JWTAuthenticator) JWKS() ([]byte, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.rawJWKS == nil {
+		return nil, a.fetchErr
+	}
+	return a.rawJWKS, nil
+}
+
+func (a *JWTAuthenticator) Mode() Mode { return ModeJWT }
+
+func (a *JWTAuthenticator) AuthenticateRequest(r *http.Request) (*Identity, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return nil, ErrUnauthorized
+	}
+	return a.verify(token)
+}
+
+// AuthenticateCredentials accepts the bearer token passed as password by
+// MediaMTX's authMethod=http callback (username is conventionally "jwt" or
+// empty in that flow).
+func (a *JWTAuthenticator) AuthenticateCredentials(username, password string) (*Identity, error) {
+	if password == "" {
+		return nil, ErrUnauthorized
+	}
+	return a.verify(password)
+}
+
+func (a *JWTAuthenticator) verify(raw string) (*Identity, error) {
+	a.mu.RLock()
+	keyFunc := a.keyFunc
+	a.mu.RUnlock()
+	if keyFunc == nil {
+		return nil, ErrUnauthorized
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(raw, claims, keyFunc)
+	if err != nil || !token.Valid {
+		return nil, ErrUnauthorized
+	}
+
+	sub, _ := claims["sub"].(string)
+	perms, err := decodePermissionsClaim(claims[a.claimName])
+	if err != nil {
+		return nil, ErrForbidden
+	}
+	return &Identity{Subject: sub, Permissions: perms}, nil
+}
+
+// decodePermissionsClaim converts the claim value (decoded by the JWT
+// library as generic JSON — []interface{} of map[string]interface{}) into
+// []Permission.
+func decodePermissionsClaim(v interface{}) ([]Permission, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var perms []Permission
+	if err := json.Unmarshal(raw, &perms); err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
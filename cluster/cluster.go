@@ -0,0 +1,113 @@
+// Package cluster elects a single leader among stream-server replicas that
+// share one SQL store (see the store package), via a DB-row-lease
+// protocol: one row in cluster_leader holds {holder, term, expires_at}, and
+// every replica periodically tries to claim it with an UPDATE guarded by
+// "expires_at < now() OR holder = self". Only the leader should run
+// MediaMTX reconciliation; followers still serve reads and must proxy
+// writes to the leader.
+package cluster
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LeaseTTL is how long a claimed lease stays valid without renewal before
+// another replica may claim it.
+const LeaseTTL = 15 * time.Second
+
+// RenewInterval is how often the leader renews its lease, and how often
+// followers retry claiming an expired one.
+const RenewInterval = 5 * time.Second
+
+// Status is the cluster state reported by GET /api/cluster.
+type Status struct {
+	Self   string   `json:"self"`
+	Leader string   `json:"leader"`
+	Peers  []string `json:"peers"`
+	Term   int      `json:"term"`
+}
+
+// Elector runs DB-row-lease leader election for one replica, identified by
+// self (its externally reachable base URL, also used as the
+// /internal/apply target by followers).
+type Elector struct {
+	db    *sql.DB
+	ph    func(n int) string
+	self  string
+	peers []string
+
+	mu     sync.RWMutex
+	leader string
+	term   int
+}
+
+// NewElector creates an Elector backed by db, creating and seeding the
+// cluster_leader table if it doesn't exist yet.
+func NewElector(db *sql.DB, ph func(n int) string, self string, peers []string) (*Elector, error) {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS cluster_leader (
+		id         INTEGER PRIMARY KEY,
+		holder     TEXT NOT NULL,
+		term       INTEGER NOT NULL,
+		expires_at TEXT NOT NULL
+	)`); err != nil {
+		return nil, fmt.Errorf("cluster: cannot create cluster_leader: %w", err)
+	}
+
+	var seeded int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM cluster_leader WHERE id = 1`).Scan(&seeded); err != nil {
+		return nil, fmt.Errorf("cluster: cannot read cluster_leader: %w", err)
+	}
+	if seeded == 0 {
+		query := fmt.Sprintf(`INSERT INTO cluster_leader (id, holder, term, expires_at) VALUES (1, %s, 0, %s)`, ph(1), ph(2))
+		if _, err := db.Exec(query, "", time.Unix(0, 0).UTC().Format(time.RFC3339)); err != nil {
+			return nil, fmt.Errorf("cluster: cannot seed cluster_leader: %w", err)
+		}
+	}
+
+	return &Elector{db: db, ph: ph, self: self, peers: peers}, nil
+}
+
+// Claim attempts to become (or remain) leader: it claims the lease if it
+// has expired, or renews it if self already holds it. Either way it
+// refreshes Status() with the lease's current holder and term.
+func (e *Elector) Claim() error {
+	now := time.Now().UTC()
+	nowStr := now.Format(time.RFC3339)
+	expires := now.Add(LeaseTTL).Format(time.RFC3339)
+
+	query := fmt.Sprintf(`UPDATE cluster_leader SET holder = %s, term = term + 1, expires_at = %s
+		WHERE id = 1 AND (expires_at < %s OR holder = %s)`,
+		e.ph(1), e.ph(2), e.ph(3), e.ph(4))
+	if _, err := e.db.Exec(query, e.self, expires, nowStr, e.self); err != nil {
+		return fmt.Errorf("cluster: claim failed: %w", err)
+	}
+
+	var holder string
+	var term int
+	if err := e.db.QueryRow(`SELECT holder, term FROM cluster_leader WHERE id = 1`).Scan(&holder, &term); err != nil {
+		return fmt.Errorf("cluster: cannot read cluster_leader: %w", err)
+	}
+
+	e.mu.Lock()
+	e.leader = holder
+	e.term = term
+	e.mu.Unlock()
+	return nil
+}
+
+// IsLeader reports whether self currently holds the lease.
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader == e.self
+}
+
+// Status returns the cluster state as last observed by Claim.
+func (e *Elector) Status() Status {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return Status{Self: e.self, Leader: e.leader, Peers: e.peers, Term: e.term}
+}
@@ -0,0 +1,122 @@
+// Package metrics registers stream-server's Prometheus collectors and the
+// HTTP instrumentation middleware that feeds them.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every collector stream-server exposes at GET /metrics.
+type Registry struct {
+	StreamsTotal      prometheus.Gauge
+	StreamStatus      *prometheus.GaugeVec
+	APIRequestsTotal  *prometheus.CounterVec
+	APIRequestLatency *prometheus.HistogramVec
+	MediaMTXUp        prometheus.Gauge
+	PathBytesSent     *prometheus.GaugeVec
+	PathReaders       *prometheus.GaugeVec
+}
+
+// NewRegistry creates and registers all collectors against the default
+// Prometheus registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		StreamsTotal: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "stream_server_streams_total",
+			Help: "Number of streams currently configured.",
+		}),
+		StreamStatus: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "stream_server_stream_status",
+			Help: "1 if the stream is currently in this status, 0 otherwise.",
+		}, []string{"name", "status"}),
+		APIRequestsTotal: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "stream_server_api_requests_total",
+			Help: "Total API requests, by route and status code.",
+		}, []string{"route", "code"}),
+		APIRequestLatency: promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "stream_server_api_request_duration_seconds",
+			Help:    "API request latency in seconds, by route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route"}),
+		MediaMTXUp: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "stream_server_mediamtx_up",
+			Help: "1 if the MediaMTX control API is reachable, 0 otherwise.",
+		}),
+		PathBytesSent: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "stream_server_mediamtx_path_bytes_sent",
+			Help: "Bytes sent so far on a MediaMTX path, as reported by /v3/paths/list.",
+		}, []string{"name"}),
+		PathReaders: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "stream_server_mediamtx_path_readers",
+			Help: "Number of active readers on a MediaMTX path.",
+		}, []string{"name"}),
+	}
+}
+
+// Handler returns the HTTP handler to mount at GET /metrics.
+func (r *Registry) Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// SetStreamStatus records which single status (connecting/online/offline) a
+// stream is currently in, zeroing the others.
+func (r *Registry) SetStreamStatus(name string, statuses []string, current string) {
+	for _, status := range statuses {
+		value := 0.0
+		if status == current {
+			value = 1.0
+		}
+		r.StreamStatus.WithLabelValues(name, status).Set(value)
+	}
+}
+
+// DeleteStream removes every per-stream label series for name, so a deleted
+// stream's gauges don't linger forever across add/delete cycles. statuses
+// must match whatever was passed to SetStreamStatus for this stream.
+func (r *Registry) DeleteStream(name string, statuses []string) {
+	for _, status := range statuses {
+		r.StreamStatus.DeleteLabelValues(name, status)
+	}
+	r.PathBytesSent.DeleteLabelValues(name)
+	r.PathReaders.DeleteLabelValues(name)
+}
+
+// Instrument wraps next with a middleware that records request counts and
+// latency per route. route should be a low-cardinality label such as the
+// mux pattern ("GET /api/streams"), not the raw URL path.
+func (r *Registry) Instrument(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, req)
+
+		r.APIRequestsTotal.WithLabelValues(route, strconv.Itoa(rec.status)).Inc()
+		r.APIRequestLatency.WithLabelValues(route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// statusRecorder captures the status code a handler wrote, since
+// http.ResponseWriter doesn't expose it after the fact.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush forwards to the wrapped ResponseWriter when it supports flushing, so
+// streaming handlers such as Server-Sent Events keep working when wrapped.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,149 @@
+package sources
+
+import "strings"
+
+// hasScheme reports whether url starts with any of the given schemes
+// (e.g. "rtsp://", "rtsps://").
+func hasScheme(url string, schemes ...string) bool {
+	for _, scheme := range schemes {
+		if strings.HasPrefix(url, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+type rtspDetector struct{}
+
+func (rtspDetector) Info() KindInfo {
+	return KindInfo{
+		Kind:        KindRTSP,
+		Schemes:     []string{"rtsp://", "rtsps://"},
+		RequiresURL: true,
+		Description: "Pull from an existing RTSP/RTSPS source",
+	}
+}
+
+func (rtspDetector) Detect(req Request) bool {
+	return req.Kind == string(KindRTSP) || hasScheme(req.SourceURL, "rtsp://", "rtsps://")
+}
+
+func (rtspDetector) Resolve(req Request) (Resolved, error) {
+	r := Resolved{Kind: KindRTSP, Source: req.SourceURL}
+	if req.RTSPTransport != "" {
+		r.Extra = map[string]interface{}{"rtspTransport": req.RTSPTransport}
+	}
+	return r, nil
+}
+
+type rtmpDetector struct{}
+
+func (rtmpDetector) Info() KindInfo {
+	return KindInfo{
+		Kind:        KindRTMP,
+		Schemes:     []string{"rtmp://", "rtmps://"},
+		RequiresURL: true,
+		Description: "Pull from an existing RTMP/RTMPS source",
+	}
+}
+
+func (rtmpDetector) Detect(req Request) bool {
+	return req.Kind == string(KindRTMP) || hasScheme(req.SourceURL, "rtmp://", "rtmps://")
+}
+
+func (rtmpDetector) Resolve(req Request) (Resolved, error) {
+	return Resolved{Kind: KindRTMP, Source: req.SourceURL}, nil
+}
+
+type srtDetector struct{}
+
+func (srtDetector) Info() KindInfo {
+	return KindInfo{
+		Kind:        KindSRT,
+		Schemes:     []string{"srt://"},
+		RequiresURL: true,
+		Description: "Pull from an existing SRT source",
+	}
+}
+
+func (srtDetector) Detect(req Request) bool {
+	return req.Kind == string(KindSRT) || hasScheme(req.SourceURL, "srt://")
+}
+
+func (srtDetector) Resolve(req Request) (Resolved, error) {
+	source := req.SourceURL
+	if req.SRTStreamID != "" && !strings.Contains(source, "streamid=") {
+		sep := "?"
+		if strings.Contains(source, "?") {
+			sep = "&"
+		}
+		source = source + sep + "streamid=" + req.SRTStreamID
+	}
+	return Resolved{Kind: KindSRT, Source: source}, nil
+}
+
+type udpDetector struct{}
+
+func (udpDetector) Info() KindInfo {
+	return KindInfo{
+		Kind:        KindUDP,
+		Schemes:     []string{"udp://"},
+		RequiresURL: true,
+		Description: "Pull an MPEG-TS multicast/unicast UDP stream",
+	}
+}
+
+func (udpDetector) Detect(req Request) bool {
+	return req.Kind == string(KindUDP) || hasScheme(req.SourceURL, "udp://")
+}
+
+func (udpDetector) Resolve(req Request) (Resolved, error) {
+	return Resolved{Kind: KindUDP, Source: req.SourceURL}, nil
+}
+
+type whepDetector struct{}
+
+func (whepDetector) Info() KindInfo {
+	return KindInfo{
+		Kind:        KindWHEP,
+		Schemes:     []string{"whep://", "http://", "https://"},
+		RequiresURL: true,
+		Description: "Pull from a remote WHEP (WebRTC) endpoint",
+	}
+}
+
+func (whepDetector) Detect(req Request) bool {
+	if req.Kind == string(KindWHEP) {
+		return true
+	}
+	return hasScheme(req.SourceURL, "whep://") || strings.Contains(req.SourceURL, "whep")
+}
+
+func (whepDetector) Resolve(req Request) (Resolved, error) {
+	source := req.SourceURL
+	if hasScheme(source, "whep://") {
+		source = "http://" + strings.TrimPrefix(source, "whep://")
+	}
+	return Resolved{Kind: KindWHEP, Source: source}, nil
+}
+
+// publisherDetector matches requests with no source URL: MediaMTX leaves
+// the path's "source" empty and waits for a client to push to it over
+// RTSP/RTMP/SRT/WHIP.
+type publisherDetector struct{}
+
+func (publisherDetector) Info() KindInfo {
+	return KindInfo{
+		Kind:        KindPublisher,
+		RequiresURL: false,
+		Description: "Client pushes to us (RTSP announce, RTMP, SRT, or WHIP)",
+	}
+}
+
+func (publisherDetector) Detect(req Request) bool {
+	return req.Kind == string(KindPublisher) || req.SourceURL == ""
+}
+
+func (publisherDetector) Resolve(req Request) (Resolved, error) {
+	return Resolved{Kind: KindPublisher, Source: "publisher"}, nil
+}
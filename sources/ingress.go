@@ -0,0 +1,35 @@
+package sources
+
+import "fmt"
+
+// Ports carries the externally-reachable MediaMTX ports needed to build
+// ingress URLs operators can paste into encoders like OBS.
+type Ports struct {
+	Host string
+	RTSP string
+	RTMP string
+	SRT  string
+}
+
+// IngressURLs returns the URLs a client can push name to, regardless of the
+// path's configured Kind: MediaMTX accepts RTSP announce, RTMP, and SRT
+// publishes onto any path name that exists. srtStreamID overrides the SRT
+// URL's streamid query param when the caller requested one; otherwise it
+// defaults to "publish:<name>".
+func IngressURLs(name string, ports Ports, srtStreamID string) map[string]string {
+	urls := map[string]string{}
+	if ports.RTSP != "" {
+		urls["rtsp"] = fmt.Sprintf("rtsp://%s:%s/%s", ports.Host, ports.RTSP, name)
+	}
+	if ports.RTMP != "" {
+		urls["rtmp"] = fmt.Sprintf("rtmp://%s:%s/%s", ports.Host, ports.RTMP, name)
+	}
+	if ports.SRT != "" {
+		streamID := srtStreamID
+		if streamID == "" {
+			streamID = "publish:" + name
+		}
+		urls["srt"] = fmt.Sprintf("srt://%s:%s?streamid=%s", ports.Host, ports.SRT, streamID)
+	}
+	return urls
+}
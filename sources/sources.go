@@ -0,0 +1,90 @@
+// Package sources classifies and resolves the stream ingress URLs
+// stream-server accepts (RTSP, RTMP, SRT, raw UDP/MPEG-TS, WHEP pull, and
+// publisher push) into the fields MediaMTX needs on a path's "source"
+// config, via pluggable Detectors.
+package sources
+
+import "fmt"
+
+// Kind identifies one supported ingest protocol.
+type Kind string
+
+const (
+	KindRTSP      Kind = "rtsp"
+	KindRTMP      Kind = "rtmp"
+	KindSRT       Kind = "srt"
+	KindUDP       Kind = "udp"
+	KindWHEP      Kind = "whep"
+	KindPublisher Kind = "publisher"
+)
+
+// KindInfo describes a supported ingest kind so the web UI can render a
+// dynamic form for it. Returned by GET /api/sources/kinds.
+type KindInfo struct {
+	Kind           Kind     `json:"kind"`
+	Schemes        []string `json:"schemes"`
+	RequiresURL    bool     `json:"requiresUrl"`
+	RequiredFields []string `json:"requiredFields,omitempty"`
+	Description    string   `json:"description"`
+}
+
+// Request is the subset of AddStreamRequest needed to classify and resolve
+// a source.
+type Request struct {
+	Kind          string // optional explicit hint, e.g. "publisher"
+	SourceURL     string // e.g. "rtsp://...", "srt://...", empty for publisher
+	SRTStreamID   string // optional SRT streamid, appended only when SourceURL has none
+	RTSPTransport string // optional: "tcp" or "udp"
+}
+
+// Resolved carries the MediaMTX path config fields a Detector produced.
+type Resolved struct {
+	Kind   Kind
+	Source string
+	// Extra holds additional MediaMTX path fields the kind requires, e.g.
+	// {"rtspTransport": "tcp"} or {"sourceFingerprint": "..."}.
+	Extra map[string]interface{}
+}
+
+// Detector recognizes one Kind of source and resolves it into MediaMTX
+// path configuration fields.
+type Detector interface {
+	Info() KindInfo
+	Detect(req Request) bool
+	Resolve(req Request) (Resolved, error)
+}
+
+// registry is checked in order; publisherDetector must stay last since it
+// matches whenever SourceURL is empty.
+var registry = []Detector{
+	rtspDetector{},
+	rtmpDetector{},
+	srtDetector{},
+	udpDetector{},
+	whepDetector{},
+	publisherDetector{},
+}
+
+// Kinds lists every registered Detector's KindInfo.
+func Kinds() []KindInfo {
+	infos := make([]KindInfo, 0, len(registry))
+	for _, d := range registry {
+		infos = append(infos, d.Info())
+	}
+	return infos
+}
+
+// Detect classifies req against every registered Detector in order and
+// resolves it into MediaMTX path fields.
+func Detect(req Request) (Resolved, error) {
+	for _, d := range registry {
+		if d.Detect(req) {
+			info := d.Info()
+			if info.RequiresURL && req.SourceURL == "" {
+				return Resolved{}, fmt.Errorf("sources: kind=%q requires a sourceUrl", info.Kind)
+			}
+			return d.Resolve(req)
+		}
+	}
+	return Resolved{}, fmt.Errorf("sources: no detector recognizes kind=%q url=%q", req.Kind, req.SourceURL)
+}
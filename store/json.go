@@ -0,0 +1,129 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// JSONStore persists streams as a single JSON array, rewriting the whole
+// file on every change. It's the original stream-server backend: simple,
+// but it doesn't scale past one node.
+type JSONStore struct {
+	mu      sync.RWMutex
+	streams map[string]*Stream
+	path    string
+}
+
+// NewJSONStore loads path (if it exists) and returns a Store backed by it.
+func NewJSONStore(path string) (*JSONStore, error) {
+	s := &JSONStore{streams: make(map[string]*Stream), path: path}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *JSONStore) load() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("store: cannot read %s: %w", s.path, err)
+	}
+
+	var streams []*Stream
+	if err := json.Unmarshal(data, &streams); err != nil {
+		return fmt.Errorf("store: cannot parse %s: %w", s.path, err)
+	}
+	for _, st := range streams {
+		s.streams[st.Name] = st
+	}
+	return nil
+}
+
+func (s *JSONStore) save() error {
+	s.mu.RLock()
+	streams := make([]*Stream, 0, len(s.streams))
+	for _, st := range s.streams {
+		streams = append(streams, st)
+	}
+	s.mu.RUnlock()
+
+	data, err := json.MarshalIndent(streams, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+func (s *JSONStore) Add(st *Stream) error {
+	s.mu.Lock()
+	if _, exists := s.streams[st.Name]; exists {
+		s.mu.Unlock()
+		return ErrConflict
+	}
+	st.UpdatedAt = time.Now().Format(time.RFC3339)
+	st.Version = 1
+	s.streams[st.Name] = st
+	s.mu.Unlock()
+	return s.save()
+}
+
+func (s *JSONStore) Get(name string) (*Stream, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	st, ok := s.streams[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *st
+	return &cp, nil
+}
+
+func (s *JSONStore) List() ([]*Stream, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*Stream, 0, len(s.streams))
+	for _, st := range s.streams {
+		cp := *st
+		result = append(result, &cp)
+	}
+	return result, nil
+}
+
+func (s *JSONStore) Update(st *Stream) error {
+	s.mu.Lock()
+	existing, ok := s.streams[st.Name]
+	if !ok {
+		s.mu.Unlock()
+		return ErrNotFound
+	}
+	if existing.Version != st.Version {
+		s.mu.Unlock()
+		return ErrConflict
+	}
+	st.Version = existing.Version + 1
+	st.UpdatedAt = time.Now().Format(time.RFC3339)
+	s.streams[st.Name] = st
+	s.mu.Unlock()
+	return s.save()
+}
+
+func (s *JSONStore) Delete(name string) error {
+	s.mu.Lock()
+	if _, ok := s.streams[name]; !ok {
+		s.mu.Unlock()
+		return ErrNotFound
+	}
+	delete(s.streams, name)
+	s.mu.Unlock()
+	return s.save()
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"sort"
+)
+
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrations embed.FS
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrations embed.FS
+
+// runMigrations applies every *.sql file under dir (in filename order) that
+// isn't already recorded in the schema_migrations table, each inside its
+// own transaction. ph formats a single positional placeholder ("?" for
+// SQLite, "$1" for Postgres).
+func runMigrations(db *sql.DB, fsys embed.FS, dir string, ph func(n int) string) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (name TEXT PRIMARY KEY)`); err != nil {
+		return fmt.Errorf("store: cannot create schema_migrations: %w", err)
+	}
+
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("store: cannot list migrations: %w", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var applied int
+		row := db.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM schema_migrations WHERE name = %s`, ph(1)), name)
+		if err := row.Scan(&applied); err != nil {
+			return fmt.Errorf("store: cannot check migration %s: %w", name, err)
+		}
+		if applied > 0 {
+			continue
+		}
+
+		sqlBytes, err := fsys.ReadFile(dir + "/" + name)
+		if err != nil {
+			return fmt.Errorf("store: cannot read migration %s: %w", name, err)
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("store: cannot start migration tx: %w", err)
+		}
+		if _, err := tx.Exec(string(sqlBytes)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("store: migration %s failed: %w", name, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`INSERT INTO schema_migrations (name) VALUES (%s)`, ph(1)), name); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("store: cannot record migration %s: %w", name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("store: cannot commit migration %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package store
+
+import "fmt"
+
+// New opens the Store selected by driver ("json", "sqlite", or "postgres").
+// dsn is the JSON file path for "json", or a driver-specific connection
+// string otherwise.
+func New(driver, dsn string) (Store, error) {
+	switch driver {
+	case "", "json":
+		return NewJSONStore(dsn)
+	case "sqlite":
+		return NewSQLiteStore(dsn)
+	case "postgres":
+		return NewPostgresStore(dsn)
+	default:
+		return nil, fmt.Errorf("store: unknown STORE_DRIVER %q", driver)
+	}
+}
+
+// MigrateFromJSON reads every stream out of the legacy JSON store at
+// jsonPath and inserts it into dst, for the one-shot
+// --migrate-from-json CLI flag. Streams that already exist in dst are
+// skipped rather than overwritten.
+func MigrateFromJSON(jsonPath string, dst Store) (imported, skipped int, err error) {
+	src, err := NewJSONStore(jsonPath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	streams, err := src.List()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, st := range streams {
+		st.Version = 0 // let Add assign version 1 in the destination
+		if err := dst.Add(st); err != nil {
+			if err == ErrConflict {
+				skipped++
+				continue
+			}
+			return imported, skipped, fmt.Errorf("store: cannot import %s: %w", st.Name, err)
+		}
+		imported++
+	}
+	return imported, skipped, nil
+}
@@ -0,0 +1,27 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/lib/pq" // Postgres driver
+)
+
+// NewPostgresStore opens a Postgres database at dsn and runs its migrations.
+func NewPostgresStore(dsn string) (Store, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("store: cannot open postgres: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("store: cannot reach postgres: %w", err)
+	}
+
+	ph := func(n int) string { return fmt.Sprintf("$%d", n) }
+	if err := runMigrations(db, postgresMigrations, "migrations/postgres", ph); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqlStore{db: db, ph: ph}, nil
+}
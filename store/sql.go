@@ -0,0 +1,165 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sqlStore implements Store against any database/sql driver using
+// positional placeholders built by ph. SQLiteStore and PostgresStore are
+// thin constructors around it.
+type sqlStore struct {
+	db *sql.DB
+	ph func(n int) string
+}
+
+const streamColumns = `name, label, kind, source_url, rtsp_transport, srt_stream_id, status, created_at,
+	ingress_urls, record_enabled, record_format, record_segment_duration, record_retention, updated_at, version`
+
+// DB exposes the *sql.DB and placeholder formatter backing s, for features
+// like cluster leader election that need direct SQL access shared across
+// replicas. It fails for the JSON store, which has no shared database.
+func DB(s Store) (*sql.DB, func(n int) string, error) {
+	sq, ok := s.(*sqlStore)
+	if !ok {
+		return nil, nil, fmt.Errorf("store: clustering requires STORE_DRIVER=sqlite or postgres, not a JSON store")
+	}
+	return sq.db, sq.ph, nil
+}
+
+func (s *sqlStore) Add(st *Stream) error {
+	ingress, err := json.Marshal(st.IngressURLs)
+	if err != nil {
+		return err
+	}
+	st.Version = 1
+	st.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	query := fmt.Sprintf(`INSERT INTO streams (%s) VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)`,
+		streamColumns,
+		s.ph(1), s.ph(2), s.ph(3), s.ph(4), s.ph(5), s.ph(6), s.ph(7), s.ph(8),
+		s.ph(9), s.ph(10), s.ph(11), s.ph(12), s.ph(13), s.ph(14), s.ph(15))
+	_, err = s.db.Exec(query,
+		st.Name, st.Label, st.Kind, st.SourceURL, st.RTSPTransport, st.SRTStreamID, st.Status, st.CreatedAt,
+		string(ingress), st.RecordEnabled, st.RecordFormat, st.RecordSegmentDuration, st.RecordRetention, st.UpdatedAt, st.Version)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+	return err
+}
+
+func (s *sqlStore) Get(name string) (*Stream, error) {
+	query := fmt.Sprintf(`SELECT %s FROM streams WHERE name = %s`, streamColumns, s.ph(1))
+	row := s.db.QueryRow(query, name)
+	st, err := scanStream(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return st, err
+}
+
+func (s *sqlStore) List() ([]*Stream, error) {
+	query := fmt.Sprintf(`SELECT %s FROM streams ORDER BY name`, streamColumns)
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*Stream
+	for rows.Next() {
+		st, err := scanStream(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, st)
+	}
+	return result, rows.Err()
+}
+
+func (s *sqlStore) Update(st *Stream) error {
+	ingress, err := json.Marshal(st.IngressURLs)
+	if err != nil {
+		return err
+	}
+	newVersion := st.Version + 1
+	st.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	query := fmt.Sprintf(`UPDATE streams SET label=%s, kind=%s, source_url=%s, rtsp_transport=%s, srt_stream_id=%s,
+		status=%s, ingress_urls=%s, record_enabled=%s, record_format=%s, record_segment_duration=%s,
+		record_retention=%s, updated_at=%s, version=%s WHERE name=%s AND version=%s`,
+		s.ph(1), s.ph(2), s.ph(3), s.ph(4), s.ph(5), s.ph(6), s.ph(7), s.ph(8), s.ph(9), s.ph(10),
+		s.ph(11), s.ph(12), s.ph(13), s.ph(14), s.ph(15))
+	result, err := s.db.Exec(query,
+		st.Label, st.Kind, st.SourceURL, st.RTSPTransport, st.SRTStreamID,
+		st.Status, string(ingress), st.RecordEnabled, st.RecordFormat, st.RecordSegmentDuration,
+		st.RecordRetention, st.UpdatedAt, newVersion, st.Name, st.Version)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		if _, err := s.Get(st.Name); err == ErrNotFound {
+			return ErrNotFound
+		}
+		return ErrConflict
+	}
+	st.Version = newVersion
+	return nil
+}
+
+func (s *sqlStore) Delete(name string) error {
+	query := fmt.Sprintf(`DELETE FROM streams WHERE name = %s`, s.ph(1))
+	result, err := s.db.Exec(query, name)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanStream(row rowScanner) (*Stream, error) {
+	var st Stream
+	var ingress string
+	if err := row.Scan(
+		&st.Name, &st.Label, &st.Kind, &st.SourceURL, &st.RTSPTransport, &st.SRTStreamID, &st.Status, &st.CreatedAt,
+		&ingress, &st.RecordEnabled, &st.RecordFormat, &st.RecordSegmentDuration, &st.RecordRetention, &st.UpdatedAt, &st.Version,
+	); err != nil {
+		return nil, err
+	}
+	if ingress != "" {
+		if err := json.Unmarshal([]byte(ingress), &st.IngressURLs); err != nil {
+			return nil, fmt.Errorf("store: cannot parse ingress_urls for %s: %w", st.Name, err)
+		}
+	}
+	return &st, nil
+}
+
+// isUniqueViolation best-effort detects a primary-key conflict across
+// SQLite and Postgres error string formats, since database/sql doesn't
+// expose a driver-agnostic error code.
+func isUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "UNIQUE constraint failed") ||
+		strings.Contains(msg, "duplicate key value violates unique constraint")
+}
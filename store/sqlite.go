@@ -0,0 +1,26 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite" // pure-Go SQLite driver, no CGO
+)
+
+// NewSQLiteStore opens (creating if needed) a SQLite database at dsn and
+// runs its migrations.
+func NewSQLiteStore(dsn string) (Store, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("store: cannot open sqlite %s: %w", dsn, err)
+	}
+	// SQLite only supports one writer at a time; serialize access here
+	// rather than hitting SQLITE_BUSY under concurrent writes.
+	db.SetMaxOpenConns(1)
+
+	if err := runMigrations(db, sqliteMigrations, "migrations/sqlite", func(n int) string { return "?" }); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqlStore{db: db, ph: func(n int) string { return "?" }}, nil
+}
@@ -0,0 +1,56 @@
+// Package store abstracts stream-server's stream persistence behind a
+// Store interface, with JSON-file, SQLite, and Postgres implementations
+// selected by STORE_DRIVER. Stream carries an UpdatedAt timestamp and a
+// Version for optimistic concurrency, so multiple API nodes sharing one
+// database don't clobber each other's writes.
+package store
+
+import "errors"
+
+// ErrNotFound is returned by Get/Update/Delete when no stream has the
+// given name.
+var ErrNotFound = errors.New("store: stream not found")
+
+// ErrConflict is returned by Update when the caller's Stream.Version does
+// not match the currently stored version.
+var ErrConflict = errors.New("store: version conflict, stream was updated concurrently")
+
+// Stream is a configured camera/source, as persisted by any Store backend.
+type Stream struct {
+	Name          string `json:"name"`
+	Label         string `json:"label"`
+	Kind          string `json:"kind"`
+	SourceURL     string `json:"sourceUrl"`
+	RTSPTransport string `json:"rtspTransport,omitempty"`
+	SRTStreamID   string `json:"srtStreamId,omitempty"`
+	Status        string `json:"status"`
+	CreatedAt     string `json:"createdAt"`
+
+	IngressURLs map[string]string `json:"ingressUrls,omitempty"`
+
+	RecordEnabled         bool   `json:"recordEnabled"`
+	RecordFormat          string `json:"recordFormat,omitempty"`
+	RecordSegmentDuration int64  `json:"recordSegmentDuration,omitempty"` // nanoseconds, time.Duration
+	RecordRetention       int64  `json:"recordRetention,omitempty"`       // nanoseconds, time.Duration
+
+	UpdatedAt string `json:"updatedAt"`
+	Version   int    `json:"version"`
+}
+
+// Store is the persistence interface every driver implements.
+type Store interface {
+	// Add inserts a new stream. It returns ErrConflict if name already exists.
+	Add(s *Stream) error
+	// Get returns the stream named name, or ErrNotFound.
+	Get(name string) (*Stream, error)
+	// List returns every stored stream.
+	List() ([]*Stream, error)
+	// Update replaces the stream matching s.Name, requiring s.Version to
+	// equal the currently stored version (optimistic concurrency), then
+	// increments it. Returns ErrConflict on a stale version or ErrNotFound
+	// if the stream no longer exists.
+	Update(s *Stream) error
+	// Delete removes the stream named name. It returns ErrNotFound if it
+	// does not exist.
+	Delete(name string) error
+}